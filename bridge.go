@@ -0,0 +1,75 @@
+package phantomjs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+)
+
+// doJSON sends a JSON-encoded request to the bridge's HTTP server and
+// decodes the JSON response into v. params may be nil. v may be nil if
+// the caller does not care about the response body.
+func (p *Process) doJSON(method, path string, params, v interface{}) error {
+	return p.doJSONContext(context.Background(), method, path, params, v)
+}
+
+// doJSONContext is like doJSON but aborts the in-flight HTTP request to
+// the bridge, and returns ctx.Err(), as soon as ctx is canceled.
+func (p *Process) doJSONContext(ctx context.Context, method, path string, params, v interface{}) error {
+	p.mu.Lock()
+	baseURL := p.baseURL
+	client := p.client
+	p.mu.Unlock()
+
+	if baseURL == "" {
+		return fmt.Errorf("phantomjs: process not open")
+	}
+
+	var body []byte
+	if params != nil {
+		b, err := json.Marshal(params)
+		if err != nil {
+			return fmt.Errorf("phantomjs: marshal request: %s", err)
+		}
+		body = b
+	}
+
+	req, err := http.NewRequest(method, baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("phantomjs: new request: %s", err)
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("phantomjs: request %s: %s", path, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("phantomjs: read response %s: %s", path, err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("phantomjs: %s returned %d: %s", path, resp.StatusCode, bytes.TrimSpace(respBody))
+	}
+
+	if v == nil || len(bytes.TrimSpace(respBody)) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(respBody, v); err != nil {
+		return fmt.Errorf("phantomjs: unmarshal response %s: %s", path, err)
+	}
+	return nil
+}
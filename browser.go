@@ -0,0 +1,89 @@
+package phantomjs
+
+import "net/http"
+
+// Browser drives a headless browser instance capable of creating and
+// navigating pages. phantomjs.Process (via PhantomJSBrowser) and
+// cdp.Browser both implement it, so callers can target either a
+// PhantomJS process or headless Chrome through the same API.
+type Browser interface {
+	// Open starts the underlying browser process.
+	Open() error
+
+	// Close terminates the underlying browser process.
+	Close() error
+
+	// CreateWebPage opens a new page (tab) on the browser.
+	CreateWebPage() (Page, error)
+}
+
+// Page represents a single browser tab driven by a Browser. It exposes
+// the subset of WebPage's functionality that has a natural equivalent
+// on every supported backend.
+type Page interface {
+	// Open navigates the page to url and waits for it to finish loading.
+	Open(url string) error
+
+	// Close releases the page on the browser.
+	Close() error
+
+	// Content returns the HTML content of the page.
+	Content() (string, error)
+
+	// EvaluateJavaScript evaluates src, a JavaScript function
+	// expression, in the context of the page and returns its result.
+	EvaluateJavaScript(src string) (interface{}, error)
+
+	// SetCookies sets the cookies visible to the page.
+	SetCookies(cookies []*http.Cookie) error
+
+	// SwitchToFrameName switches the page's active frame to the one
+	// with the given name.
+	SwitchToFrameName(name string) error
+
+	// PaperSize returns the sizing options used when printing the page.
+	PaperSize() (PaperSize, error)
+
+	// SetPaperSize sets the sizing options used when printing the page.
+	SetPaperSize(sz PaperSize) error
+}
+
+// PhantomJSBrowser adapts Process to the Browser interface.
+type PhantomJSBrowser struct {
+	*Process
+}
+
+// NewPhantomJSBrowser returns a new PhantomJSBrowser backed by a fresh
+// Process.
+func NewPhantomJSBrowser() *PhantomJSBrowser {
+	return &PhantomJSBrowser{Process: NewProcess()}
+}
+
+// CreateWebPage opens a new page on the phantomjs process and adapts it
+// to the Page interface.
+func (b *PhantomJSBrowser) CreateWebPage() (Page, error) {
+	return &phantomJSPage{WebPage: b.Process.CreateWebPage()}, nil
+}
+
+// phantomJSPage adapts WebPage's *E methods to the Page interface.
+type phantomJSPage struct {
+	*WebPage
+}
+
+func (p *phantomJSPage) Content() (string, error) { return p.WebPage.ContentE() }
+
+func (p *phantomJSPage) EvaluateJavaScript(src string) (interface{}, error) {
+	return p.WebPage.EvaluateJavaScriptE(src)
+}
+
+func (p *phantomJSPage) SetCookies(cookies []*http.Cookie) error {
+	return p.WebPage.SetCookiesE(cookies)
+}
+
+func (p *phantomJSPage) SwitchToFrameName(name string) error {
+	return p.WebPage.SwitchToFrameNameE(name)
+}
+
+func (p *phantomJSPage) PaperSize() (PaperSize, error) { return p.WebPage.PaperSizeE() }
+
+func (p *phantomJSPage) SetPaperSize(sz PaperSize) error { return p.WebPage.SetPaperSizeE(sz) }
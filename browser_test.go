@@ -0,0 +1,93 @@
+package phantomjs_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/middlemost/phantomjs"
+	"github.com/middlemost/phantomjs/cdp"
+)
+
+// newBrowser returns the Browser backend selected by the
+// PHANTOMJS_BACKEND environment variable ("phantomjs", the default, or
+// "cdp"). This lets the interface-level tests below run against either
+// backend without duplicating them.
+func newBrowser() phantomjs.Browser {
+	switch os.Getenv("PHANTOMJS_BACKEND") {
+	case "cdp":
+		return cdp.NewBrowser()
+	default:
+		return phantomjs.NewPhantomJSBrowser()
+	}
+}
+
+// Ensure a Browser backend can open a page and navigate it to a URL.
+func TestBrowser_Open(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<html><body>OK</body></html>"))
+	}))
+	defer srv.Close()
+
+	b := newBrowser()
+	if err := b.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+
+	page, err := b.CreateWebPage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer page.Close()
+
+	if err := page.Open(srv.URL); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Ensure a Browser backend can set cookies on a page.
+func TestBrowser_Cookies(t *testing.T) {
+	b := newBrowser()
+	if err := b.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+
+	page, err := b.CreateWebPage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer page.Close()
+
+	cookies := []*http.Cookie{{Domain: ".example.com", Name: "NAME", Path: "/", Value: "VALUE"}}
+	if err := page.SetCookies(cookies); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Ensure a Browser backend can set the paper size used for printing.
+func TestBrowser_PaperSize(t *testing.T) {
+	b := newBrowser()
+	if err := b.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+
+	page, err := b.CreateWebPage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer page.Close()
+
+	sz := phantomjs.PaperSize{Format: "A4"}
+	if err := page.SetPaperSize(sz); err != nil {
+		t.Fatal(err)
+	}
+	if other, err := page.PaperSize(); err != nil {
+		t.Fatal(err)
+	} else if other != sz {
+		t.Fatalf("unexpected size: %#v", other)
+	}
+}
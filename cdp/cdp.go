@@ -0,0 +1,137 @@
+// Package cdp implements the phantomjs.Browser and phantomjs.Page
+// interfaces on top of headless Chrome, speaking the Chrome DevTools
+// Protocol (CDP) over its WebSocket debugger endpoint. It is a drop-in
+// alternative to the PhantomJS backend for callers who only depend on
+// the phantomjs.Browser / phantomjs.Page interfaces.
+package cdp
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/middlemost/phantomjs"
+)
+
+// DefaultPath is the default path to the chrome binary.
+const DefaultPath = "google-chrome"
+
+// DefaultPort is the default remote-debugging port passed to chrome.
+const DefaultPort = 9222
+
+// devtoolsReadyTimeout is how long Open waits for chrome's HTTP devtools
+// endpoint to come up before giving up.
+const devtoolsReadyTimeout = 10 * time.Second
+
+// Browser drives a headless Chrome instance over the Chrome DevTools
+// Protocol. It implements phantomjs.Browser.
+type Browser struct {
+	mu  sync.Mutex
+	cmd *exec.Cmd
+
+	path string
+	port int
+}
+
+// NewBrowser returns a new instance of Browser.
+func NewBrowser() *Browser {
+	return &Browser{path: DefaultPath, port: DefaultPort}
+}
+
+// Path returns the path to the chrome binary used by the browser.
+func (b *Browser) Path() string { return b.path }
+
+// SetPath sets the path to the chrome binary. Must be called before Open.
+func (b *Browser) SetPath(path string) { b.path = path }
+
+// Port returns the remote-debugging port used by chrome.
+func (b *Browser) Port() int { return b.port }
+
+// SetPort sets the remote-debugging port used by chrome. Must be called
+// before Open.
+func (b *Browser) SetPort(port int) { b.port = port }
+
+// Open starts headless chrome and waits for its devtools HTTP endpoint
+// to become available.
+func (b *Browser) Open() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.cmd != nil {
+		return fmt.Errorf("cdp: browser already open")
+	}
+
+	cmd := exec.Command(b.path,
+		"--headless",
+		"--disable-gpu",
+		fmt.Sprintf("--remote-debugging-port=%d", b.port),
+	)
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("cdp: start: %s", err)
+	}
+
+	deadline := time.Now().Add(devtoolsReadyTimeout)
+	for {
+		if _, err := http.Get(b.versionURL()); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			cmd.Process.Kill()
+			cmd.Wait()
+			return fmt.Errorf("cdp: timed out waiting for devtools endpoint")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	b.cmd = cmd
+	return nil
+}
+
+// Close terminates the chrome process.
+func (b *Browser) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.cmd == nil {
+		return nil
+	}
+	err := b.cmd.Process.Kill()
+	b.cmd.Wait()
+	b.cmd = nil
+	return err
+}
+
+func (b *Browser) versionURL() string {
+	return "http://127.0.0.1:" + strconv.Itoa(b.port) + "/json/version"
+}
+
+// CreateWebPage opens a new tab via the /json/new devtools HTTP endpoint
+// and attaches to it over its per-target WebSocket debugger URL.
+func (b *Browser) CreateWebPage() (phantomjs.Page, error) {
+	resp, err := http.Post("http://127.0.0.1:"+strconv.Itoa(b.port)+"/json/new", "", nil)
+	if err != nil {
+		return nil, fmt.Errorf("cdp: create target: %s", err)
+	}
+	defer resp.Body.Close()
+
+	var target struct {
+		ID                   string `json:"id"`
+		WebSocketDebuggerURL string `json:"webSocketDebuggerUrl"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&target); err != nil {
+		return nil, fmt.Errorf("cdp: decode target: %s", err)
+	}
+
+	conn, err := dial(target.WebSocketDebuggerURL)
+	if err != nil {
+		return nil, fmt.Errorf("cdp: dial target: %s", err)
+	}
+
+	return &Page{targetID: target.ID, conn: conn}, nil
+}
+
+var _ phantomjs.Browser = (*Browser)(nil)
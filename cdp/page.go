@@ -0,0 +1,311 @@
+package cdp
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/middlemost/phantomjs"
+)
+
+// Page drives a single Chrome tab over its CDP WebSocket debugger
+// connection. It implements phantomjs.Page by mapping each operation to
+// the relevant Target/Page/Runtime/Network domain methods.
+//
+// mu serializes every round trip on conn: CDP multiplexes command
+// responses and unsolicited domain events on the same WebSocket, so two
+// goroutines reading concurrently could each consume frames meant for
+// the other. Holding mu for the full request-to-matching-response (or
+// request-to-matching-event) exchange keeps that from happening.
+type Page struct {
+	targetID string
+	conn     *conn
+
+	mu     sync.Mutex
+	nextID int
+
+	// contextID, when non-zero, is the Runtime execution context of the
+	// frame most recently selected via SwitchToFrameName. It is threaded
+	// through subsequent EvaluateJavaScript calls so they run in that
+	// frame rather than the top-level one.
+	contextID int
+
+	// paperSize holds the print options last set via SetPaperSize. It is
+	// only returned by PaperSize; nothing in this package sends it to
+	// Chrome.
+	paperSize phantomjs.PaperSize
+}
+
+// rpcRequest is a CDP JSON-RPC request.
+type rpcRequest struct {
+	ID     int         `json:"id"`
+	Method string      `json:"method"`
+	Params interface{} `json:"params,omitempty"`
+}
+
+// rpcMessage is anything that can arrive on a CDP WebSocket connection:
+// either a JSON-RPC response to a call (ID set), or an unsolicited
+// domain event (Method set, ID unset).
+type rpcMessage struct {
+	ID     int             `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// call issues a CDP method call and waits for its matching response,
+// discarding any domain events received in the meantime.
+func (p *Page) call(method string, params interface{}, result interface{}) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.callLocked(method, params, result)
+}
+
+// callLocked is call's implementation. Callers must hold p.mu; it is
+// used directly (rather than through call) when a sequence of calls and
+// event waits must run without another goroutine's call interleaving,
+// such as in Open.
+func (p *Page) callLocked(method string, params interface{}, result interface{}) error {
+	p.nextID++
+	id := p.nextID
+
+	body, err := json.Marshal(rpcRequest{ID: id, Method: method, Params: params})
+	if err != nil {
+		return fmt.Errorf("cdp: marshal %s: %s", method, err)
+	}
+	if err := p.conn.writeText(body); err != nil {
+		return fmt.Errorf("cdp: send %s: %s", method, err)
+	}
+
+	for {
+		msg, err := p.readMessageLocked()
+		if err != nil {
+			return fmt.Errorf("cdp: read %s response: %s", method, err)
+		}
+		if msg.Method != "" {
+			continue // unsolicited domain event
+		}
+		if msg.ID != id {
+			continue // response to an unrelated call
+		}
+		if msg.Error != nil {
+			return fmt.Errorf("cdp: %s: %s", method, msg.Error.Message)
+		}
+		if result == nil || len(msg.Result) == 0 {
+			return nil
+		}
+		return json.Unmarshal(msg.Result, result)
+	}
+}
+
+// waitForEventLocked blocks until a domain event named method arrives,
+// discarding call responses and other events in the meantime. Callers
+// must hold p.mu.
+func (p *Page) waitForEventLocked(method string) error {
+	for {
+		msg, err := p.readMessageLocked()
+		if err != nil {
+			return fmt.Errorf("cdp: wait for %s: %s", method, err)
+		}
+		if msg.Method == method {
+			return nil
+		}
+	}
+}
+
+// readMessageLocked reads and decodes the next frame from conn. Callers
+// must hold p.mu.
+func (p *Page) readMessageLocked() (rpcMessage, error) {
+	raw, err := p.conn.readText()
+	if err != nil {
+		return rpcMessage{}, err
+	}
+	var msg rpcMessage
+	if err := json.Unmarshal(raw, &msg); err != nil {
+		return rpcMessage{}, fmt.Errorf("cdp: unmarshal message: %s", err)
+	}
+	return msg, nil
+}
+
+// Open navigates the page to url via Page.navigate and blocks until
+// Chrome reports Page.loadEventFired, so Content and EvaluateJavaScript
+// observe the fully loaded document once Open returns.
+func (p *Page) Open(url string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := p.callLocked("Page.enable", nil, nil); err != nil {
+		return err
+	}
+
+	var result struct {
+		ErrorText string `json:"errorText"`
+	}
+	if err := p.callLocked("Page.navigate", map[string]string{"url": url}, &result); err != nil {
+		return err
+	}
+	if result.ErrorText != "" {
+		return fmt.Errorf("cdp: navigate %s: %s", url, result.ErrorText)
+	}
+
+	return p.waitForEventLocked("Page.loadEventFired")
+}
+
+// Close closes the tab via Target.closeTarget.
+func (p *Page) Close() error {
+	return p.call("Target.closeTarget", map[string]string{"targetId": p.targetID}, nil)
+}
+
+// Content returns the page's serialized HTML via
+// Runtime.evaluate("document.documentElement.outerHTML").
+func (p *Page) Content() (string, error) {
+	v, err := p.EvaluateJavaScript(`function() { return document.documentElement.outerHTML }`)
+	if err != nil {
+		return "", err
+	}
+	s, _ := v.(string)
+	return s, nil
+}
+
+// EvaluateJavaScript evaluates src, a JavaScript function expression, via
+// Runtime.evaluate and returns its result. If SwitchToFrameName has
+// selected a child frame, src runs in that frame's execution context
+// instead of the page's top-level one.
+func (p *Page) EvaluateJavaScript(src string) (interface{}, error) {
+	p.mu.Lock()
+	contextID := p.contextID
+	p.mu.Unlock()
+
+	var result struct {
+		Result struct {
+			Value interface{} `json:"value"`
+		} `json:"result"`
+		ExceptionDetails *struct {
+			Text string `json:"text"`
+		} `json:"exceptionDetails"`
+	}
+
+	params := map[string]interface{}{
+		"expression":    fmt.Sprintf("(%s)()", src),
+		"returnByValue": true,
+	}
+	if contextID != 0 {
+		params["contextId"] = contextID
+	}
+
+	if err := p.call("Runtime.evaluate", params, &result); err != nil {
+		return nil, err
+	}
+	if result.ExceptionDetails != nil {
+		return nil, fmt.Errorf("cdp: evaluate: %s", result.ExceptionDetails.Text)
+	}
+	return result.Result.Value, nil
+}
+
+// SetCookies sets the page's cookies via Network.setCookies.
+func (p *Page) SetCookies(cookies []*http.Cookie) error {
+	if err := p.call("Network.enable", nil, nil); err != nil {
+		return err
+	}
+
+	type cdpCookie struct {
+		Name     string `json:"name"`
+		Value    string `json:"value"`
+		Domain   string `json:"domain,omitempty"`
+		Path     string `json:"path,omitempty"`
+		Secure   bool   `json:"secure,omitempty"`
+		HTTPOnly bool   `json:"httpOnly,omitempty"`
+		Expires  int64  `json:"expires,omitempty"`
+	}
+
+	cdpCookies := make([]cdpCookie, len(cookies))
+	for i, c := range cookies {
+		cdpCookies[i] = cdpCookie{
+			Name:     c.Name,
+			Value:    c.Value,
+			Domain:   c.Domain,
+			Path:     c.Path,
+			Secure:   c.Secure,
+			HTTPOnly: c.HttpOnly,
+		}
+		if !c.Expires.IsZero() {
+			cdpCookies[i].Expires = c.Expires.Unix()
+		}
+	}
+
+	return p.call("Network.setCookies", map[string]interface{}{"cookies": cdpCookies}, nil)
+}
+
+// SwitchToFrameName switches the active frame to the child frame named
+// name by resolving it to a CDP frame ID via Page.getFrameTree, then
+// creating an isolated execution context in it via
+// Page.createIsolatedWorld. The resulting execution context ID is
+// recorded and threaded through subsequent EvaluateJavaScript calls.
+func (p *Page) SwitchToFrameName(name string) error {
+	var tree struct {
+		FrameTree struct {
+			Frame struct {
+				ID   string `json:"id"`
+				Name string `json:"name"`
+			} `json:"frame"`
+			ChildFrames []struct {
+				Frame struct {
+					ID   string `json:"id"`
+					Name string `json:"name"`
+				} `json:"frame"`
+			} `json:"childFrames"`
+		} `json:"frameTree"`
+	}
+	if err := p.call("Page.getFrameTree", nil, &tree); err != nil {
+		return err
+	}
+
+	for _, child := range tree.FrameTree.ChildFrames {
+		if child.Frame.Name != name {
+			continue
+		}
+
+		var world struct {
+			ExecutionContextID int `json:"executionContextId"`
+		}
+		if err := p.call("Page.createIsolatedWorld", map[string]interface{}{
+			"frameId":   child.Frame.ID,
+			"worldName": "phantomjs-bridge",
+		}, &world); err != nil {
+			return err
+		}
+
+		p.mu.Lock()
+		p.contextID = world.ExecutionContextID
+		p.mu.Unlock()
+		return nil
+	}
+
+	return fmt.Errorf("cdp: frame not found: %s", name)
+}
+
+// PaperSize is not directly queryable from Chrome; CDP's
+// Page.printToPDF takes print options but does not expose the last
+// configuration used, so callers must track the desired size themselves
+// via SetPaperSize.
+func (p *Page) PaperSize() (phantomjs.PaperSize, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.paperSize, nil
+}
+
+// SetPaperSize records sz so that PaperSize returns it. It issues no
+// network traffic; this package does not call Page.printToPDF, so sz
+// does not reach Chrome.
+func (p *Page) SetPaperSize(sz phantomjs.PaperSize) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.paperSize = sz
+	return nil
+}
+
+var _ phantomjs.Page = (*Page)(nil)
@@ -0,0 +1,189 @@
+package cdp
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+)
+
+// wsAcceptGUID is the magic string defined by RFC 6455 for computing the
+// Sec-WebSocket-Accept handshake response.
+const wsAcceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+const (
+	wsOpText  = 0x1
+	wsOpClose = 0x8
+)
+
+// conn is a minimal RFC 6455 WebSocket client connection, sufficient for
+// exchanging the JSON text frames, one message per frame, used by the
+// Chrome DevTools Protocol. It does not support frame fragmentation or
+// extensions, neither of which CDP uses.
+type conn struct {
+	nc net.Conn
+	br *bufio.Reader
+
+	mu sync.Mutex
+}
+
+// dial opens a WebSocket connection to the given ws:// URL, performing
+// the HTTP Upgrade handshake.
+func dial(rawurl string) (*conn, error) {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return nil, err
+	}
+
+	nc, err := net.Dial("tcp", u.Host)
+	if err != nil {
+		return nil, err
+	}
+
+	key := make([]byte, 16)
+	if _, err := rand.Read(key); err != nil {
+		nc.Close()
+		return nil, err
+	}
+	encodedKey := base64.StdEncoding.EncodeToString(key)
+
+	req, err := http.NewRequest("GET", "http://"+u.Host+u.RequestURI(), nil)
+	if err != nil {
+		nc.Close()
+		return nil, err
+	}
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Sec-WebSocket-Key", encodedKey)
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	if err := req.Write(nc); err != nil {
+		nc.Close()
+		return nil, err
+	}
+
+	br := bufio.NewReader(nc)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		nc.Close()
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		nc.Close()
+		return nil, fmt.Errorf("cdp: unexpected handshake status: %s", resp.Status)
+	}
+
+	want := acceptKey(encodedKey)
+	if resp.Header.Get("Sec-WebSocket-Accept") != want {
+		nc.Close()
+		return nil, fmt.Errorf("cdp: handshake accept key mismatch")
+	}
+
+	return &conn{nc: nc, br: br}, nil
+}
+
+func acceptKey(clientKey string) string {
+	h := sha1.New()
+	io.WriteString(h, clientKey+wsAcceptGUID)
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+// writeText sends p as a single, masked text frame, as required of
+// WebSocket clients by RFC 6455.
+func (c *conn) writeText(p []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var header []byte
+	header = append(header, 0x80|wsOpText)
+
+	mask := make([]byte, 4)
+	if _, err := rand.Read(mask); err != nil {
+		return err
+	}
+
+	switch {
+	case len(p) <= 125:
+		header = append(header, 0x80|byte(len(p)))
+	case len(p) <= 0xffff:
+		header = append(header, 0x80|126)
+		var ext [2]byte
+		binary.BigEndian.PutUint16(ext[:], uint16(len(p)))
+		header = append(header, ext[:]...)
+	default:
+		header = append(header, 0x80|127)
+		var ext [8]byte
+		binary.BigEndian.PutUint64(ext[:], uint64(len(p)))
+		header = append(header, ext[:]...)
+	}
+	header = append(header, mask...)
+
+	payload := make([]byte, len(p))
+	for i, b := range p {
+		payload[i] = b ^ mask[i%4]
+	}
+
+	if _, err := c.nc.Write(header); err != nil {
+		return err
+	}
+	_, err := c.nc.Write(payload)
+	return err
+}
+
+// readText reads the next text frame from the server. Server-to-client
+// frames are never masked (RFC 6455 section 5.1).
+func (c *conn) readText() ([]byte, error) {
+	for {
+		first, err := c.br.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		opcode := first & 0x0f
+
+		second, err := c.br.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		length := uint64(second & 0x7f)
+
+		switch length {
+		case 126:
+			var ext [2]byte
+			if _, err := io.ReadFull(c.br, ext[:]); err != nil {
+				return nil, err
+			}
+			length = uint64(binary.BigEndian.Uint16(ext[:]))
+		case 127:
+			var ext [8]byte
+			if _, err := io.ReadFull(c.br, ext[:]); err != nil {
+				return nil, err
+			}
+			length = binary.BigEndian.Uint64(ext[:])
+		}
+
+		payload := make([]byte, length)
+		if _, err := io.ReadFull(c.br, payload); err != nil {
+			return nil, err
+		}
+
+		if opcode == wsOpClose {
+			return nil, io.EOF
+		}
+		if opcode != wsOpText {
+			continue
+		}
+		return payload, nil
+	}
+}
+
+func (c *conn) Close() error {
+	return c.nc.Close()
+}
@@ -0,0 +1,223 @@
+package phantomjs
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultPath is the default path to the phantomjs binary.
+const DefaultPath = "phantomjs"
+
+// DefaultPort is the default bind port for the bridge's HTTP server.
+const DefaultPort = 20202
+
+// bridgeReadyTimeout is how long Open waits for the bridge to report
+// that it is listening before giving up.
+const bridgeReadyTimeout = 10 * time.Second
+
+// Process represents a PhantomJS process and the HTTP bridge used to
+// communicate with it.
+type Process struct {
+	mu      sync.Mutex
+	cmd     *exec.Cmd
+	baseURL string
+	client  *http.Client
+
+	path string
+	port int
+
+	// Stdout and Stderr, if set, receive the subprocess' output streams.
+	Stdout io.Writer
+	Stderr io.Writer
+
+	// pagesMu and pages track every WebPage created on this process,
+	// keyed by id, so the request-decision server (see
+	// ensureDecisionServer in resource.go) can look up the right page's
+	// OnResourceRequested callback for an incoming decision request.
+	pagesMu sync.Mutex
+	pages   map[int]*WebPage
+
+	// decisionOnce and decisionLn guard the lazily-started request-
+	// decision server; see ensureDecisionServer in resource.go.
+	decisionOnce sync.Once
+	decisionLn   net.Listener
+}
+
+// NewProcess returns a new instance of Process.
+func NewProcess() *Process {
+	return &Process{
+		path:   DefaultPath,
+		port:   DefaultPort,
+		client: http.DefaultClient,
+	}
+}
+
+// Path returns the path to the phantomjs binary used by the process.
+func (p *Process) Path() string { return p.path }
+
+// SetPath sets the path to the phantomjs binary. Must be called before Open.
+func (p *Process) SetPath(path string) { p.path = path }
+
+// Port returns the bind port used by the bridge's HTTP server.
+func (p *Process) Port() int { return p.port }
+
+// SetPort sets the bind port used by the bridge's HTTP server. Must be
+// called before Open.
+func (p *Process) SetPort(port int) { p.port = port }
+
+// Open starts the phantomjs process and waits for the HTTP bridge to
+// become available.
+func (p *Process) Open() error {
+	return p.OpenContext(context.Background())
+}
+
+// OpenContext is like Open but aborts startup and returns ctx.Err() if
+// ctx is canceled before the bridge reports that it is ready. A canceled
+// ctx kills the subprocess the same way a timed-out wait does.
+func (p *Process) OpenContext(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.cmd != nil {
+		return fmt.Errorf("phantomjs: process already open")
+	}
+
+	script, err := writeShimScript()
+	if err != nil {
+		return fmt.Errorf("phantomjs: write shim: %s", err)
+	}
+
+	cmd := exec.Command(p.path, script, strconv.Itoa(p.port))
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("phantomjs: stdout pipe: %s", err)
+	}
+
+	var stderrBuf bytes.Buffer
+	if p.Stderr != nil {
+		cmd.Stderr = io.MultiWriter(&stderrBuf, p.Stderr)
+	} else {
+		cmd.Stderr = &stderrBuf
+	}
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("phantomjs: start: %s", err)
+	}
+
+	ready := make(chan error, 1)
+	go func() {
+		ready <- waitForReady(stdout, p.Stdout)
+	}()
+
+	select {
+	case err := <-ready:
+		if err != nil {
+			cmd.Process.Kill()
+			cmd.Wait()
+			return fmt.Errorf("phantomjs: %s: %s", err, strings.TrimSpace(stderrBuf.String()))
+		}
+	case <-ctx.Done():
+		cmd.Process.Kill()
+		cmd.Wait()
+		return ctx.Err()
+	case <-time.After(bridgeReadyTimeout):
+		cmd.Process.Kill()
+		cmd.Wait()
+		return fmt.Errorf("phantomjs: timed out waiting for bridge: %s", strings.TrimSpace(stderrBuf.String()))
+	}
+
+	p.cmd = cmd
+	p.baseURL = fmt.Sprintf("http://127.0.0.1:%d", p.port)
+
+	return nil
+}
+
+// waitForReady scans the subprocess' stdout for the shim's "ready" line,
+// copying every line it sees to out (if non-nil).
+func waitForReady(r io.Reader, out io.Writer) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if out != nil {
+			fmt.Fprintln(out, line)
+		}
+		if line == shimReadyLine {
+			return nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return fmt.Errorf("bridge exited before signaling ready")
+}
+
+// Close terminates the phantomjs process.
+func (p *Process) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.decisionLn != nil {
+		p.decisionLn.Close()
+	}
+
+	if p.cmd == nil {
+		return nil
+	}
+
+	err := p.cmd.Process.Kill()
+	p.cmd.Wait()
+	p.cmd = nil
+	p.baseURL = ""
+	return err
+}
+
+// CreateWebPage creates a new web page on the phantomjs process.
+func (p *Process) CreateWebPage() *WebPage {
+	var resp struct {
+		ID int `json:"id"`
+	}
+	// The bridge always returns an id for a newly created page; errors
+	// here would only indicate the process is unreachable, which later
+	// calls against the returned WebPage will surface via their *E
+	// variants.
+	p.doJSON("POST", "/create_page", nil, &resp)
+	return newWebPage(p, resp.ID)
+}
+
+// registerPage records pg so the request-decision server can look it up
+// by id. See ensureDecisionServer in resource.go.
+func (p *Process) registerPage(pg *WebPage) {
+	p.pagesMu.Lock()
+	if p.pages == nil {
+		p.pages = make(map[int]*WebPage)
+	}
+	p.pages[pg.id] = pg
+	p.pagesMu.Unlock()
+}
+
+// unregisterPage removes the page with the given id, called from
+// WebPage.Close.
+func (p *Process) unregisterPage(id int) {
+	p.pagesMu.Lock()
+	delete(p.pages, id)
+	p.pagesMu.Unlock()
+}
+
+// pageByID returns the page registered with the given id, or nil if none
+// is found (e.g. it has already been closed).
+func (p *Process) pageByID(id int) *WebPage {
+	p.pagesMu.Lock()
+	defer p.pagesMu.Unlock()
+	return p.pages[id]
+}
@@ -0,0 +1,280 @@
+package phantomjs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+)
+
+// ResourceActionType describes how a ResourceRequest should be handled
+// by a WebPage's OnResourceRequested callback.
+type ResourceActionType int
+
+const (
+	// ResourceActionAllow lets the request proceed unmodified.
+	ResourceActionAllow ResourceActionType = iota
+
+	// ResourceActionAbort cancels the request, as if the network were
+	// unreachable.
+	ResourceActionAbort
+
+	// ResourceActionRedirect changes the request's URL before it is
+	// sent, via ResourceAction.URL.
+	ResourceActionRedirect
+)
+
+// ResourceAction is returned by an OnResourceRequested callback to
+// decide the fate of a ResourceRequest.
+type ResourceAction struct {
+	Type ResourceActionType `json:"type"`
+	URL  string             `json:"url,omitempty"`
+}
+
+// Allow lets a request proceed unmodified.
+func Allow() ResourceAction { return ResourceAction{Type: ResourceActionAllow} }
+
+// Abort cancels a request.
+func Abort() ResourceAction { return ResourceAction{Type: ResourceActionAbort} }
+
+// Redirect changes a request's URL before it is sent.
+func Redirect(url string) ResourceAction {
+	return ResourceAction{Type: ResourceActionRedirect, URL: url}
+}
+
+// ResourceRequest describes an outgoing network request made by a
+// WebPage, as reported by PhantomJS' onResourceRequested.
+type ResourceRequest struct {
+	ID      int         `json:"id"`
+	URL     string      `json:"url"`
+	Method  string      `json:"method"`
+	Headers http.Header `json:"headers"`
+}
+
+// ResourceResponse describes a network response received by a WebPage,
+// as reported by PhantomJS' onResourceReceived.
+type ResourceResponse struct {
+	ID      int         `json:"id"`
+	URL     string      `json:"url"`
+	Status  int         `json:"status"`
+	Headers http.Header `json:"headers"`
+}
+
+// ResourceError describes a failed network request, as reported by
+// PhantomJS' onResourceError.
+type ResourceError struct {
+	ID          int    `json:"id"`
+	URL         string `json:"url"`
+	ErrorCode   int    `json:"errorCode"`
+	ErrorString string `json:"errorString"`
+}
+
+// resourceEvent is a single item long-polled from the bridge's
+// /resource_events endpoint. Exactly one of Response or Error is set,
+// matching Kind. Requests are not delivered this way; see
+// resourceDecisionRequest.
+type resourceEvent struct {
+	Kind     string            `json:"kind"` // "response" or "error"
+	Response *ResourceResponse `json:"response,omitempty"`
+	Error    *ResourceError    `json:"error,omitempty"`
+}
+
+// resourceDecisionRequest is the body the shim posts synchronously, from
+// inside onResourceRequested, to the process' request-decision server.
+type resourceDecisionRequest struct {
+	PageID  int             `json:"pageId"`
+	Request ResourceRequest `json:"request"`
+}
+
+// resourceHooks holds the callbacks registered on a WebPage, along with
+// the machinery used to long-poll the bridge for response/error events.
+type resourceHooks struct {
+	mu sync.Mutex
+
+	onRequested func(*ResourceRequest) ResourceAction
+	onReceived  func(*ResourceResponse)
+	onError     func(*ResourceError)
+
+	started bool
+	cancel  context.CancelFunc
+}
+
+// OnResourceRequested registers fn to decide the fate of every outgoing
+// network request made by the page. PhantomJS blocks the page-side
+// request until fn returns, so fn should be fast; it defaults to
+// ResourceActionAllow if no callback is registered.
+//
+// PhantomJS only honors a request's Abort/ChangeUrl if they are called
+// synchronously from inside onResourceRequested, so the decision can't
+// go through the resource_events long-poll used by OnResourceReceived
+// and OnResourceError. Registering fn instead starts the process'
+// request-decision server (see ensureDecisionServer), which the shim
+// calls synchronously to run fn and get its answer.
+func (pg *WebPage) OnResourceRequested(fn func(*ResourceRequest) ResourceAction) {
+	pg.hooks().mu.Lock()
+	pg.hooks().onRequested = fn
+	pg.hooks().mu.Unlock()
+	if err := pg.process.ensureDecisionServer(); err != nil {
+		panic(err)
+	}
+}
+
+// OnResourceReceived registers fn to be called with every network
+// response received by the page.
+func (pg *WebPage) OnResourceReceived(fn func(*ResourceResponse)) {
+	pg.hooks().mu.Lock()
+	pg.hooks().onReceived = fn
+	pg.hooks().mu.Unlock()
+	pg.startResourcePolling()
+}
+
+// OnResourceError registers fn to be called with every network request
+// that fails.
+func (pg *WebPage) OnResourceError(fn func(*ResourceError)) {
+	pg.hooks().mu.Lock()
+	pg.hooks().onError = fn
+	pg.hooks().mu.Unlock()
+	pg.startResourcePolling()
+}
+
+// hooks lazily initializes and returns the page's resourceHooks.
+func (pg *WebPage) hooks() *resourceHooks {
+	pg.hooksOnce.Do(func() { pg.resourceHooks = &resourceHooks{} })
+	return pg.resourceHooks
+}
+
+// startResourcePolling begins long-polling the bridge for resource
+// events, once per page, the first time any On* callback is registered.
+func (pg *WebPage) startResourcePolling() {
+	h := pg.hooks()
+
+	h.mu.Lock()
+	if h.started {
+		h.mu.Unlock()
+		return
+	}
+	h.started = true
+	ctx, cancel := context.WithCancel(context.Background())
+	h.cancel = cancel
+	h.mu.Unlock()
+
+	go pg.pollResourceEvents(ctx)
+}
+
+// stopResourcePolling cancels the page's resource event polling loop, if
+// running. It is called from Close.
+func (pg *WebPage) stopResourcePolling() {
+	if pg.resourceHooks == nil {
+		return
+	}
+	pg.resourceHooks.mu.Lock()
+	cancel := pg.resourceHooks.cancel
+	pg.resourceHooks.mu.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+}
+
+// maxResourcePollFailures bounds how many consecutive bridge errors
+// pollResourceEvents tolerates, via errorSleeper, before giving up on the
+// page's resource event stream rather than spinning forever.
+const maxResourcePollFailures = 100
+
+// pollResourceEvents long-polls the bridge's /resource_events endpoint,
+// dispatching each response/error event to its registered callback.
+func (pg *WebPage) pollResourceEvents(ctx context.Context) {
+	errorSleeper := DefaultSleeper()
+	failures := 0
+
+	for {
+		var event resourceEvent
+		err := pg.process.doJSONContext(ctx, "GET", pg.path("resource_events"), nil, &event)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			failures++
+			if failures > maxResourcePollFailures {
+				return
+			}
+			if err := errorSleeper.Sleep(ctx); err != nil {
+				return
+			}
+			continue
+		}
+		failures = 0
+
+		switch event.Kind {
+		case "response":
+			h := pg.hooks()
+			h.mu.Lock()
+			onReceived := h.onReceived
+			h.mu.Unlock()
+			if onReceived != nil {
+				onReceived(event.Response)
+			}
+		case "error":
+			h := pg.hooks()
+			h.mu.Lock()
+			onError := h.onError
+			h.mu.Unlock()
+			if onError != nil {
+				onError(event.Error)
+			}
+		}
+	}
+}
+
+// ensureDecisionServer lazily starts the process' request-decision
+// server, the first time any page registers an OnResourceRequested
+// callback, and tells the shim where to reach it via the
+// resource_decision_url field. The shim calls this server synchronously
+// from inside onResourceRequested, since PhantomJS only honors an
+// Abort/ChangeUrl call made before that handler returns.
+func (p *Process) ensureDecisionServer() error {
+	var startErr error
+	p.decisionOnce.Do(func() {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			startErr = fmt.Errorf("phantomjs: listen for resource decisions: %s", err)
+			return
+		}
+		p.decisionLn = ln
+
+		mux := http.NewServeMux()
+		mux.HandleFunc("/resource_decision", p.handleResourceDecision)
+		go http.Serve(ln, mux)
+
+		url := fmt.Sprintf("http://%s/resource_decision", ln.Addr().String())
+		startErr = p.doJSON("POST", "/resource_decision_url", url, nil)
+	})
+	return startErr
+}
+
+// handleResourceDecision answers the shim's synchronous call with the
+// ResourceAction returned by the target page's OnResourceRequested
+// callback, defaulting to ResourceActionAllow if the page has none
+// registered or is no longer known (e.g. already closed).
+func (p *Process) handleResourceDecision(w http.ResponseWriter, r *http.Request) {
+	var req resourceDecisionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	action := ResourceAction{Type: ResourceActionAllow}
+	if pg := p.pageByID(req.PageID); pg != nil {
+		h := pg.hooks()
+		h.mu.Lock()
+		onRequested := h.onRequested
+		h.mu.Unlock()
+		if onRequested != nil {
+			action = onRequested(&req.Request)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(action)
+}
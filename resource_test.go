@@ -0,0 +1,80 @@
+package phantomjs_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/middlemost/phantomjs"
+)
+
+// Ensure web page can abort a resource request before it reaches the
+// network.
+func TestWebPage_OnResourceRequested_Abort(t *testing.T) {
+	var blocked bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			w.Write([]byte(`<html><body><img src="/blocked.png"/></body></html>`))
+		case "/blocked.png":
+			blocked = true
+			w.Write([]byte(`SHOULD NOT BE FETCHED`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	p := MustOpenNewProcess()
+	defer p.MustClose()
+
+	page := p.CreateWebPage()
+	defer page.Close()
+
+	page.OnResourceRequested(func(req *phantomjs.ResourceRequest) phantomjs.ResourceAction {
+		if req.URL == srv.URL+"/blocked.png" {
+			return phantomjs.Abort()
+		}
+		return phantomjs.Allow()
+	})
+
+	if err := page.Open(srv.URL); err != nil {
+		t.Fatal(err)
+	}
+	if blocked {
+		t.Fatal("expected blocked resource to not be fetched")
+	}
+}
+
+// Ensure web page reports received responses via OnResourceReceived.
+func TestWebPage_OnResourceReceived(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<html><body>OK</body></html>`))
+	}))
+	defer srv.Close()
+
+	p := MustOpenNewProcess()
+	defer p.MustClose()
+
+	page := p.CreateWebPage()
+	defer page.Close()
+
+	received := make(chan *phantomjs.ResourceResponse, 1)
+	page.OnResourceReceived(func(resp *phantomjs.ResourceResponse) {
+		received <- resp
+	})
+
+	if err := page.Open(srv.URL); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case resp := <-received:
+		if resp.URL != srv.URL+"/" {
+			t.Fatalf("unexpected url: %s", resp.URL)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for a resource response")
+	}
+}
@@ -0,0 +1,323 @@
+package phantomjs
+
+import (
+	"io/ioutil"
+	"os"
+)
+
+// shimReadyLine is written to stdout by the shim script once its HTTP
+// bridge server is listening.
+const shimReadyLine = "phantomjs-bridge-ready"
+
+// shimScript is the PhantomJS-side script that starts a local HTTP
+// server and dispatches requests to page operations. It is written to a
+// temporary file and passed as the entry point when the phantomjs
+// process is started.
+//
+// Routes:
+//
+//	POST /create_page                           -> {id}
+//	GET  /page/{id}/{field}                      -> field value
+//	POST /page/{id}/{field}                      -> sets field value
+//	POST /page/{id}/open                         -> navigates, waits for load
+//	POST /page/{id}/evaluate_javascript          -> evaluates a function expression
+//	POST /page/{id}/upload_file                  -> sets an <input type="file">'s files
+//	GET  /page/{id}/resource_events               -> long-polls the next queued response/error event
+//	POST /resource_decision_url                  -> sets where onResourceRequested asks for a decision
+//	POST /page/{id}/close                        -> releases the page
+//
+// Note on resource interception: PhantomJS only honors a request's
+// abort()/changeUrl() if they are called synchronously from inside
+// onResourceRequested, so a decision can't be queued and answered later
+// like response/error events are. Instead, once a decision URL has been
+// set (see OnResourceRequested/ensureDecisionServer in resource.go),
+// onResourceRequested blocks on a synchronous XMLHttpRequest to the Go
+// side's request-decision server and applies whatever action it
+// returns before returning itself.
+const shimScript = `
+var webpage = require('webpage');
+var webserver = require('webserver');
+var system = require('system');
+
+var port = system.args[1];
+var pages = {};
+var nextPageID = 1;
+var decisionURL = null;
+
+function headersToObject(headers) {
+	var out = {};
+	(headers || []).forEach(function(h) { out[h.name] = h.value; });
+	return out;
+}
+
+function respond(response, status, data) {
+	response.statusCode = status;
+	if (data === null || typeof data === 'undefined') {
+		response.write('');
+	} else {
+		response.write(JSON.stringify(data));
+	}
+	response.close();
+}
+
+function deliverOrQueue(entry, event) {
+	if (entry.eventWaiters.length) {
+		var waiter = entry.eventWaiters.shift();
+		clearTimeout(waiter.timer);
+		respond(waiter.response, 200, event);
+	} else {
+		entry.eventQueue.push(event);
+	}
+}
+
+function requestDecision(pageID, requestData) {
+	try {
+		var xhr = new XMLHttpRequest();
+		xhr.open('POST', decisionURL, false); // synchronous: see note atop shimScript
+		xhr.setRequestHeader('Content-Type', 'application/json');
+		xhr.send(JSON.stringify({
+			pageId: pageID,
+			request: {
+				url: requestData.url,
+				method: requestData.method,
+				headers: headersToObject(requestData.headers)
+			}
+		}));
+		if (xhr.status !== 200 || !xhr.responseText) {
+			return null;
+		}
+		return JSON.parse(xhr.responseText);
+	} catch (e) {
+		return null;
+	}
+}
+
+function registerPage(p) {
+	var id = nextPageID++;
+	var entry = {
+		id: id,
+		page: p,
+		eventQueue: [],
+		eventWaiters: []
+	};
+
+	p.onResourceRequested = function(requestData, networkRequest) {
+		if (!decisionURL) {
+			return; // no OnResourceRequested callback registered; allow
+		}
+		var action = requestDecision(id, requestData);
+		if (!action) {
+			return;
+		}
+		if (action.type === 1) {
+			networkRequest.abort();
+		} else if (action.type === 2 && action.url) {
+			networkRequest.changeUrl(action.url);
+		}
+	};
+	p.onResourceReceived = function(res) {
+		if (res.stage && res.stage !== 'end') {
+			return;
+		}
+		deliverOrQueue(entry, {
+			kind: 'response',
+			response: {
+				id: res.id,
+				url: res.url,
+				status: res.status,
+				headers: headersToObject(res.headers)
+			}
+		});
+	};
+	p.onResourceError = function(resourceError) {
+		deliverOrQueue(entry, {
+			kind: 'error',
+			error: {
+				id: resourceError.id,
+				url: resourceError.url,
+				errorCode: resourceError.errorCode,
+				errorString: resourceError.errorString
+			}
+		});
+	};
+
+	pages[id] = entry;
+	return entry;
+}
+
+var fieldGetters = {
+	can_go_forward: function(e) { return e.page.canGoForward; },
+	can_go_back: function(e) { return e.page.canGoBack; },
+	clip_rect: function(e) { return e.page.clipRect; },
+	cookies: function(e) { return e.page.cookies; },
+	custom_headers: function(e) { return e.page.customHeaders; },
+	focused_frame_name: function(e) { return e.page.focusedFrameName; },
+	frame_content: function(e) { return e.page.frameContent; },
+	frame_name: function(e) { return e.page.frameName; },
+	frame_plain_text: function(e) { return e.page.framePlainText; },
+	frame_title: function(e) { return e.page.frameTitle; },
+	frame_url: function(e) { return e.page.frameUrl; },
+	frame_count: function(e) { return e.page.framesCount; },
+	frame_names: function(e) { return e.page.framesName; },
+	library_path: function(e) { return e.page.libraryPath; },
+	navigation_locked: function(e) { return e.page.navigationLocked; },
+	offline_storage_path: function(e) { return e.page.offlineStoragePath; },
+	offline_storage_quota: function(e) { return e.page.offlineStorageQuota; },
+	owns_pages: function(e) { return e.page.ownsPages; },
+	page_window_names: function(e) { return e.page.pagesWindowName; },
+	pages: function(e) {
+		return e.page.pages.map(function(p) { return registerPage(p).id; });
+	},
+	paper_size: function(e) { return e.page.paperSize; },
+	plain_text: function(e) { return e.page.plainText; },
+	scroll_position: function(e) { return e.page.scrollPosition; },
+	content: function(e) { return e.page.content; },
+	url: function(e) { return e.page.url; }
+};
+
+var fieldSetters = {
+	clip_rect: function(e, v) { e.page.clipRect = v; },
+	cookies: function(e, v) { e.page.cookies = v; },
+	custom_headers: function(e, v) { e.page.customHeaders = v; },
+	switch_to_frame_name: function(e, v) { e.page.switchToFrame(v); },
+	switch_to_frame_position: function(e, v) { e.page.switchToChildFrame(v); },
+	frame_content: function(e, v) { e.page.frameContent = v; },
+	library_path: function(e, v) { e.page.libraryPath = v; },
+	navigation_locked: function(e, v) { e.page.navigationLocked = v; },
+	owns_pages: function(e, v) { e.page.ownsPages = v; },
+	paper_size: function(e, v) { e.page.paperSize = v; },
+	scroll_position: function(e, v) { e.page.scrollPosition = v; },
+	content: function(e, v) { e.page.content = v; }
+};
+
+function parseBody(request) {
+	if (!request.postRaw) {
+		return null;
+	}
+	return JSON.parse(request.postRaw);
+}
+
+function route(request, response) {
+	if (request.method === 'POST' && request.url === '/create_page') {
+		var entry = registerPage(webpage.create());
+		respond(response, 200, {id: entry.id});
+		return;
+	}
+
+	if (request.method === 'POST' && request.url === '/resource_decision_url') {
+		decisionURL = parseBody(request);
+		respond(response, 200, null);
+		return;
+	}
+
+	var m = request.url.match(/^\/page\/(\d+)\/(.+)$/);
+	if (!m) {
+		respond(response, 404, null);
+		return;
+	}
+
+	var entry = pages[parseInt(m[1], 10)];
+	var field = m[2];
+	if (!entry) {
+		respond(response, 404, null);
+		return;
+	}
+
+	if (field === 'close') {
+		entry.page.close();
+		delete pages[entry.id];
+		respond(response, 200, null);
+		return;
+	}
+
+	if (field === 'open') {
+		var url = parseBody(request);
+		entry.page.open(url, function(status) {
+			if (status !== 'success') {
+				respond(response, 502, {error: 'failed to load ' + url});
+				return;
+			}
+			respond(response, 200, null);
+		});
+		return; // response is completed asynchronously above
+	}
+
+	if (field === 'evaluate_javascript') {
+		var src = parseBody(request);
+		var fn = new Function('return (' + src + ').apply(this, arguments);');
+		var result = entry.page.evaluate(fn);
+		respond(response, 200, result);
+		return;
+	}
+
+	if (field === 'upload_file') {
+		var params = parseBody(request);
+		var found = entry.page.evaluate(function(s) {
+			return document.querySelector(s) !== null;
+		}, params.selector);
+		if (!found) {
+			respond(response, 404, {error: 'no element matches ' + params.selector});
+			return;
+		}
+		entry.page.uploadFile(params.selector, params.paths);
+		respond(response, 200, null);
+		return;
+	}
+
+	if (field === 'resource_events' && request.method === 'GET') {
+		if (entry.eventQueue.length) {
+			respond(response, 200, entry.eventQueue.shift());
+			return;
+		}
+		var timer = setTimeout(function() {
+			var idx = entry.eventWaiters.indexOf(waiter);
+			if (idx >= 0) {
+				entry.eventWaiters.splice(idx, 1);
+			}
+			respond(response, 200, {});
+		}, 25000);
+		var waiter = {response: response, timer: timer};
+		entry.eventWaiters.push(waiter);
+		return;
+	}
+
+	if (request.method === 'GET' && fieldGetters[field]) {
+		respond(response, 200, fieldGetters[field](entry));
+		return;
+	}
+	if (request.method === 'POST' && fieldSetters[field]) {
+		fieldSetters[field](entry, parseBody(request));
+		respond(response, 200, null);
+		return;
+	}
+
+	respond(response, 404, null);
+}
+
+var server = webserver.create();
+server.listen(port, function(request, response) {
+	try {
+		route(request, response);
+	} catch (e) {
+		respond(response, 500, {error: String(e)});
+	}
+});
+
+console.log('` + shimReadyLine + `');
+`
+
+// writeShimScript writes the shim script to a temporary file and returns
+// its path.
+func writeShimScript() (string, error) {
+	f, err := ioutil.TempFile("", "phantomjs-shim-*.js")
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	if _, err := f.WriteString(shimScript); err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
@@ -0,0 +1,72 @@
+package phantomjs
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+)
+
+// ErrNotFound is returned by NotFoundSleeper's Sleep, and therefore by
+// any Wait* method configured with it, the first time the waited-for
+// condition isn't already met.
+var ErrNotFound = errors.New("phantomjs: not found")
+
+// Sleeper controls the retry delay between polling attempts in
+// WebPage.WaitFor, WaitForFunc, and WaitNavigation.
+type Sleeper interface {
+	// Sleep waits before the next polling attempt, or returns early
+	// with an error to abandon retrying. It should also respect ctx
+	// cancellation.
+	Sleep(ctx context.Context) error
+}
+
+// DefaultSleeper returns a Sleeper with exponential backoff from 50ms,
+// capped at 2s before up to 50% jitter is added, suitable for waiting out page loads and
+// DOM updates that settle within a few seconds.
+func DefaultSleeper() Sleeper {
+	return &exponentialSleeper{base: 50 * time.Millisecond, max: 2 * time.Second}
+}
+
+// NotFoundSleeper returns a Sleeper that never waits: its first Sleep
+// call fails immediately with ErrNotFound. Use it when a condition that
+// isn't already met should be treated as a hard failure rather than
+// something worth retrying for.
+func NotFoundSleeper() Sleeper {
+	return notFoundSleeper{}
+}
+
+// exponentialSleeper implements Sleeper with a doubling delay capped at
+// max, plus up to 50% jitter added after the cap, so a single Sleep can
+// last up to 1.5 times max.
+type exponentialSleeper struct {
+	base time.Duration
+	max  time.Duration
+
+	attempt int
+}
+
+func (s *exponentialSleeper) Sleep(ctx context.Context) error {
+	delay := s.base << uint(s.attempt)
+	if delay <= 0 || delay > s.max {
+		delay = s.max
+	}
+	s.attempt++
+
+	delay += time.Duration(rand.Int63n(int64(delay)/2 + 1))
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// notFoundSleeper implements Sleeper by always failing fast.
+type notFoundSleeper struct{}
+
+func (notFoundSleeper) Sleep(ctx context.Context) error { return ErrNotFound }
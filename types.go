@@ -0,0 +1,32 @@
+package phantomjs
+
+// Rect represents a rectangle used for clipping a web page.
+type Rect struct {
+	Top    int `json:"top"`
+	Left   int `json:"left"`
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+// Position represents an x/y scroll position on a web page.
+type Position struct {
+	Top  int `json:"top"`
+	Left int `json:"left"`
+}
+
+// PaperSize represents the sizing options used when printing a web page.
+type PaperSize struct {
+	Width       string           `json:"width,omitempty"`
+	Height      string           `json:"height,omitempty"`
+	Format      string           `json:"format,omitempty"`
+	Orientation string           `json:"orientation,omitempty"`
+	Margin      *PaperSizeMargin `json:"margin,omitempty"`
+}
+
+// PaperSizeMargin represents the margins around a PaperSize.
+type PaperSizeMargin struct {
+	Top    string `json:"top,omitempty"`
+	Bottom string `json:"bottom,omitempty"`
+	Left   string `json:"left,omitempty"`
+	Right  string `json:"right,omitempty"`
+}
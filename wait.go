@@ -0,0 +1,91 @@
+package phantomjs
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// defaultWaitTimeout bounds how long WaitFor and WaitForFunc will poll
+// for a condition that never becomes true, so a selector that never
+// appears fails instead of blocking indefinitely.
+const defaultWaitTimeout = 30 * time.Second
+
+// WithSleeper sets the factory used to build a new Sleeper for every
+// WaitFor, WaitForFunc, and WaitNavigation call. It returns pg so calls
+// can be chained from CreateWebPage. If never called, WebPage uses
+// DefaultSleeper.
+//
+// A factory, rather than a Sleeper value, is required because Sleepers
+// such as exponentialSleeper carry state (e.g. an attempt counter)
+// across Sleep calls; sharing one instance across separate waits would
+// carry that state over too, so a page's second wait would start backed
+// off as far as the first wait's last attempt. Pass the Sleeper
+// constructor itself - DefaultSleeper, NotFoundSleeper, or your own -
+// and a fresh instance is built per wait.
+func (pg *WebPage) WithSleeper(factory func() Sleeper) *WebPage {
+	pg.sleeperFactory = factory
+	return pg
+}
+
+// newSleeper builds a fresh Sleeper for a single wait loop, using the
+// page's configured factory, or DefaultSleeper if WithSleeper was never
+// called.
+func (pg *WebPage) newSleeper() Sleeper {
+	if pg.sleeperFactory == nil {
+		return DefaultSleeper()
+	}
+	return pg.sleeperFactory()
+}
+
+// WaitFor polls the page, via EvaluateJavaScript, until an element
+// matching selector exists in the DOM, consulting the page's configured
+// Sleeper between attempts. It removes the need for hand-rolled
+// time.Sleep loops around Open and frame transitions.
+func (pg *WebPage) WaitFor(selector string) error {
+	return pg.WaitForFunc(fmt.Sprintf(`function() { return document.querySelector(%q) !== null }`, selector))
+}
+
+// WaitForFunc polls the page, via EvaluateJavaScript, until js - a
+// JavaScript function expression - returns a truthy value, consulting
+// the page's configured Sleeper between attempts. Polling is bounded by
+// defaultWaitTimeout, so a condition that never becomes true returns an
+// error instead of blocking forever. Neither WaitFor nor WaitForFunc
+// takes a caller-supplied context; only WaitNavigation does.
+func (pg *WebPage) WaitForFunc(js string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultWaitTimeout)
+	defer cancel()
+
+	sleeper := pg.newSleeper()
+	for {
+		v, err := pg.EvaluateJavaScriptContext(ctx, js)
+		if err != nil {
+			return err
+		}
+		if ok, _ := v.(bool); ok {
+			return nil
+		}
+		if err := sleeper.Sleep(ctx); err != nil {
+			return err
+		}
+	}
+}
+
+// WaitNavigation blocks until the page's current navigation finishes
+// loading or ctx is canceled, consulting the page's configured Sleeper
+// between attempts.
+func (pg *WebPage) WaitNavigation(ctx context.Context) error {
+	sleeper := pg.newSleeper()
+	for {
+		v, err := pg.EvaluateJavaScriptContext(ctx, `function() { return document.readyState === "complete" }`)
+		if err != nil {
+			return err
+		}
+		if ok, _ := v.(bool); ok {
+			return nil
+		}
+		if err := sleeper.Sleep(ctx); err != nil {
+			return err
+		}
+	}
+}
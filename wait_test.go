@@ -0,0 +1,74 @@
+package phantomjs_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/middlemost/phantomjs"
+)
+
+// Ensure WaitFor returns once the awaited element appears, without the
+// caller having to hand-roll a time.Sleep loop.
+func TestWebPage_WaitFor(t *testing.T) {
+	p := MustOpenNewProcess()
+	defer p.MustClose()
+
+	page := p.CreateWebPage()
+	defer page.Close()
+
+	page.SetContent(`<html><body></body></html>`)
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		page.EvaluateJavaScript(`function() { document.body.innerHTML = '<div id="ready"></div>' }`)
+	}()
+
+	if err := page.WaitFor("#ready"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Ensure NotFoundSleeper fails fast instead of retrying.
+func TestWebPage_WaitFor_NotFoundSleeper(t *testing.T) {
+	p := MustOpenNewProcess()
+	defer p.MustClose()
+
+	page := p.CreateWebPage().WithSleeper(phantomjs.NotFoundSleeper)
+	defer page.Close()
+
+	page.SetContent(`<html><body></body></html>`)
+	if err := page.WaitFor("#missing"); !errors.Is(err, phantomjs.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+// Ensure WaitNavigation respects context cancellation rather than
+// polling forever.
+func TestWebPage_WaitNavigation_ContextCanceled(t *testing.T) {
+	// Server that never responds, so the page's image never loads and its
+	// document never reaches readyState "complete".
+	block := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-block
+	}))
+	defer srv.Close()
+	defer close(block)
+
+	p := MustOpenNewProcess()
+	defer p.MustClose()
+
+	page := p.CreateWebPage()
+	defer page.Close()
+
+	page.SetContent(`<html><body><img src="` + srv.URL + `/hang.png"/></body></html>`)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if err := page.WaitNavigation(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+}
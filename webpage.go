@@ -0,0 +1,779 @@
+package phantomjs
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// WebPage represents a single page owned by a phantomjs Process. It is
+// created via Process.CreateWebPage and communicates with the process
+// over the same HTTP bridge.
+type WebPage struct {
+	process *Process
+	id      int
+
+	hooksOnce     sync.Once
+	resourceHooks *resourceHooks
+
+	// sleeperFactory builds a fresh Sleeper for each WaitFor/WaitForFunc/
+	// WaitNavigation call; see WithSleeper.
+	sleeperFactory func() Sleeper
+}
+
+// newWebPage returns a new instance of WebPage attached to process.
+func newWebPage(process *Process, id int) *WebPage {
+	pg := &WebPage{process: process, id: id}
+	process.registerPage(pg)
+	return pg
+}
+
+// path returns the bridge URL path for a field on this page.
+func (pg *WebPage) path(field string) string {
+	return fmt.Sprintf("/page/%d/%s", pg.id, field)
+}
+
+// getE fetches a field from the bridge into v.
+func (pg *WebPage) getE(field string, v interface{}) error {
+	return pg.process.doJSON("GET", pg.path(field), nil, v)
+}
+
+// setE sends params to a field on the bridge.
+func (pg *WebPage) setE(field string, params interface{}) error {
+	return pg.process.doJSON("POST", pg.path(field), params, nil)
+}
+
+// getContext fetches a field from the bridge into v, aborting the
+// request if ctx is canceled before it completes.
+func (pg *WebPage) getContext(ctx context.Context, field string, v interface{}) error {
+	return pg.process.doJSONContext(ctx, "GET", pg.path(field), nil, v)
+}
+
+// setContext sends params to a field on the bridge, aborting the request
+// if ctx is canceled before it completes.
+func (pg *WebPage) setContext(ctx context.Context, field string, params interface{}) error {
+	return pg.process.doJSONContext(ctx, "POST", pg.path(field), params, nil)
+}
+
+// Close releases the page on the phantomjs process.
+func (pg *WebPage) Close() error {
+	pg.stopResourcePolling()
+	pg.process.unregisterPage(pg.id)
+	return pg.process.doJSON("POST", pg.path("close"), nil, nil)
+}
+
+// CanGoForwardE returns true if the page can navigate forward in its
+// history. It returns an error if the request to the bridge fails.
+func (pg *WebPage) CanGoForwardE() (bool, error) {
+	var v bool
+	err := pg.getE("can_go_forward", &v)
+	return v, err
+}
+
+// CanGoForward returns true if the page can navigate forward in its
+// history. It panics if the request to the bridge fails.
+func (pg *WebPage) CanGoForward() bool {
+	v, err := pg.CanGoForwardE()
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// CanGoBackE returns true if the page can navigate back in its history.
+// It returns an error if the request to the bridge fails.
+func (pg *WebPage) CanGoBackE() (bool, error) {
+	var v bool
+	err := pg.getE("can_go_back", &v)
+	return v, err
+}
+
+// CanGoBack returns true if the page can navigate back in its history.
+// It panics if the request to the bridge fails.
+func (pg *WebPage) CanGoBack() bool {
+	v, err := pg.CanGoBackE()
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// ClipRectE returns the clipping rectangle used when rendering the page.
+// It returns an error if the request to the bridge fails.
+func (pg *WebPage) ClipRectE() (Rect, error) {
+	var v Rect
+	err := pg.getE("clip_rect", &v)
+	return v, err
+}
+
+// ClipRect returns the clipping rectangle used when rendering the page.
+// It panics if the request to the bridge fails.
+func (pg *WebPage) ClipRect() Rect {
+	v, err := pg.ClipRectE()
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// SetClipRectE sets the clipping rectangle used when rendering the page.
+func (pg *WebPage) SetClipRectE(rect Rect) error {
+	return pg.setE("clip_rect", rect)
+}
+
+// SetClipRect sets the clipping rectangle used when rendering the page.
+// It panics if the request to the bridge fails.
+func (pg *WebPage) SetClipRect(rect Rect) {
+	if err := pg.SetClipRectE(rect); err != nil {
+		panic(err)
+	}
+}
+
+// CookiesE returns the cookies visible to the page. It returns an error
+// if the request to the bridge fails.
+func (pg *WebPage) CookiesE() ([]*http.Cookie, error) {
+	var v []*http.Cookie
+	err := pg.getE("cookies", &v)
+	return v, err
+}
+
+// Cookies returns the cookies visible to the page. It panics if the
+// request to the bridge fails.
+func (pg *WebPage) Cookies() []*http.Cookie {
+	v, err := pg.CookiesE()
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// SetCookiesE sets the cookies visible to the page.
+func (pg *WebPage) SetCookiesE(cookies []*http.Cookie) error {
+	return pg.setE("cookies", cookies)
+}
+
+// SetCookies sets the cookies visible to the page. It panics if the
+// request to the bridge fails.
+func (pg *WebPage) SetCookies(cookies []*http.Cookie) {
+	if err := pg.SetCookiesE(cookies); err != nil {
+		panic(err)
+	}
+}
+
+// CustomHeadersE returns the extra HTTP headers sent with every request
+// made by the page. It returns an error if the request to the bridge
+// fails.
+func (pg *WebPage) CustomHeadersE() (http.Header, error) {
+	var v http.Header
+	err := pg.getE("custom_headers", &v)
+	return v, err
+}
+
+// CustomHeaders returns the extra HTTP headers sent with every request
+// made by the page. It panics if the request to the bridge fails.
+func (pg *WebPage) CustomHeaders() http.Header {
+	v, err := pg.CustomHeadersE()
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// SetCustomHeadersE sets the extra HTTP headers sent with every request
+// made by the page.
+func (pg *WebPage) SetCustomHeadersE(hdr http.Header) error {
+	return pg.setE("custom_headers", hdr)
+}
+
+// SetCustomHeaders sets the extra HTTP headers sent with every request
+// made by the page. It panics if the request to the bridge fails.
+func (pg *WebPage) SetCustomHeaders(hdr http.Header) {
+	if err := pg.SetCustomHeadersE(hdr); err != nil {
+		panic(err)
+	}
+}
+
+// FocusedFrameNameE returns the name of the currently focused frame. It
+// returns an error if the request to the bridge fails.
+func (pg *WebPage) FocusedFrameNameE() (string, error) {
+	var v string
+	err := pg.getE("focused_frame_name", &v)
+	return v, err
+}
+
+// FocusedFrameName returns the name of the currently focused frame. It
+// panics if the request to the bridge fails.
+func (pg *WebPage) FocusedFrameName() string {
+	v, err := pg.FocusedFrameNameE()
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// SwitchToFrameNameE switches the page's active frame to the one with
+// the given name.
+func (pg *WebPage) SwitchToFrameNameE(name string) error {
+	return pg.setE("switch_to_frame_name", name)
+}
+
+// SwitchToFrameName switches the page's active frame to the one with the
+// given name. It panics if the request to the bridge fails.
+func (pg *WebPage) SwitchToFrameName(name string) {
+	if err := pg.SwitchToFrameNameE(name); err != nil {
+		panic(err)
+	}
+}
+
+// SwitchToFrameNameContext switches the page's active frame to the one
+// with the given name, aborting and returning ctx.Err() if ctx is
+// canceled before the switch completes.
+func (pg *WebPage) SwitchToFrameNameContext(ctx context.Context, name string) error {
+	return pg.setContext(ctx, "switch_to_frame_name", name)
+}
+
+// SwitchToFramePositionE switches the page's active frame to the one at
+// the given zero-based index.
+func (pg *WebPage) SwitchToFramePositionE(pos int) error {
+	return pg.setE("switch_to_frame_position", pos)
+}
+
+// SwitchToFramePosition switches the page's active frame to the one at
+// the given zero-based index. It panics if the request to the bridge
+// fails.
+func (pg *WebPage) SwitchToFramePosition(pos int) {
+	if err := pg.SwitchToFramePositionE(pos); err != nil {
+		panic(err)
+	}
+}
+
+// SwitchToFramePositionContext switches the page's active frame to the
+// one at the given zero-based index, aborting and returning ctx.Err() if
+// ctx is canceled before the switch completes.
+func (pg *WebPage) SwitchToFramePositionContext(ctx context.Context, pos int) error {
+	return pg.setContext(ctx, "switch_to_frame_position", pos)
+}
+
+// FrameContentE returns the HTML content of the active frame. It returns
+// an error if the request to the bridge fails.
+func (pg *WebPage) FrameContentE() (string, error) {
+	var v string
+	err := pg.getE("frame_content", &v)
+	return v, err
+}
+
+// FrameContent returns the HTML content of the active frame. It panics
+// if the request to the bridge fails.
+func (pg *WebPage) FrameContent() string {
+	v, err := pg.FrameContentE()
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// SetFrameContentE sets the HTML content of the active frame.
+func (pg *WebPage) SetFrameContentE(content string) error {
+	return pg.setE("frame_content", content)
+}
+
+// SetFrameContent sets the HTML content of the active frame. It panics
+// if the request to the bridge fails.
+func (pg *WebPage) SetFrameContent(content string) {
+	if err := pg.SetFrameContentE(content); err != nil {
+		panic(err)
+	}
+}
+
+// FrameNameE returns the name of the active frame. It returns an error
+// if the request to the bridge fails.
+func (pg *WebPage) FrameNameE() (string, error) {
+	var v string
+	err := pg.getE("frame_name", &v)
+	return v, err
+}
+
+// FrameName returns the name of the active frame. It panics if the
+// request to the bridge fails.
+func (pg *WebPage) FrameName() string {
+	v, err := pg.FrameNameE()
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// FramePlainTextE returns the plain-text rendering of the active frame.
+// It returns an error if the request to the bridge fails.
+func (pg *WebPage) FramePlainTextE() (string, error) {
+	var v string
+	err := pg.getE("frame_plain_text", &v)
+	return v, err
+}
+
+// FramePlainText returns the plain-text rendering of the active frame.
+// It panics if the request to the bridge fails.
+func (pg *WebPage) FramePlainText() string {
+	v, err := pg.FramePlainTextE()
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// FrameTitleE returns the title of the active frame. It returns an error
+// if the request to the bridge fails.
+func (pg *WebPage) FrameTitleE() (string, error) {
+	var v string
+	err := pg.getE("frame_title", &v)
+	return v, err
+}
+
+// FrameTitle returns the title of the active frame. It panics if the
+// request to the bridge fails.
+func (pg *WebPage) FrameTitle() string {
+	v, err := pg.FrameTitleE()
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// FrameURLE returns the URL of the active frame. It returns an error if
+// the request to the bridge fails.
+func (pg *WebPage) FrameURLE() (string, error) {
+	var v string
+	err := pg.getE("frame_url", &v)
+	return v, err
+}
+
+// FrameURL returns the URL of the active frame. It panics if the request
+// to the bridge fails.
+func (pg *WebPage) FrameURL() string {
+	v, err := pg.FrameURLE()
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// FrameCountE returns the number of frames on the page. It returns an
+// error if the request to the bridge fails.
+func (pg *WebPage) FrameCountE() (int, error) {
+	var v int
+	err := pg.getE("frame_count", &v)
+	return v, err
+}
+
+// FrameCount returns the number of frames on the page. It panics if the
+// request to the bridge fails.
+func (pg *WebPage) FrameCount() int {
+	v, err := pg.FrameCountE()
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// FrameNamesE returns the names of every frame on the page. It returns
+// an error if the request to the bridge fails.
+func (pg *WebPage) FrameNamesE() ([]string, error) {
+	var v []string
+	err := pg.getE("frame_names", &v)
+	return v, err
+}
+
+// FrameNames returns the names of every frame on the page. It panics if
+// the request to the bridge fails.
+func (pg *WebPage) FrameNames() []string {
+	v, err := pg.FrameNamesE()
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// LibraryPathE returns the path used to resolve relative script includes
+// on the page. It returns an error if the request to the bridge fails.
+func (pg *WebPage) LibraryPathE() (string, error) {
+	var v string
+	err := pg.getE("library_path", &v)
+	return v, err
+}
+
+// LibraryPath returns the path used to resolve relative script includes
+// on the page. It panics if the request to the bridge fails.
+func (pg *WebPage) LibraryPath() string {
+	v, err := pg.LibraryPathE()
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// SetLibraryPathE sets the path used to resolve relative script includes
+// on the page.
+func (pg *WebPage) SetLibraryPathE(path string) error {
+	return pg.setE("library_path", path)
+}
+
+// SetLibraryPath sets the path used to resolve relative script includes
+// on the page. It panics if the request to the bridge fails.
+func (pg *WebPage) SetLibraryPath(path string) {
+	if err := pg.SetLibraryPathE(path); err != nil {
+		panic(err)
+	}
+}
+
+// NavigationLockedE returns true if navigation away from the page is
+// disabled. It returns an error if the request to the bridge fails.
+func (pg *WebPage) NavigationLockedE() (bool, error) {
+	var v bool
+	err := pg.getE("navigation_locked", &v)
+	return v, err
+}
+
+// NavigationLocked returns true if navigation away from the page is
+// disabled. It panics if the request to the bridge fails.
+func (pg *WebPage) NavigationLocked() bool {
+	v, err := pg.NavigationLockedE()
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// SetNavigationLockedE sets whether navigation away from the page is
+// disabled.
+func (pg *WebPage) SetNavigationLockedE(v bool) error {
+	return pg.setE("navigation_locked", v)
+}
+
+// SetNavigationLocked sets whether navigation away from the page is
+// disabled. It panics if the request to the bridge fails.
+func (pg *WebPage) SetNavigationLocked(v bool) {
+	if err := pg.SetNavigationLockedE(v); err != nil {
+		panic(err)
+	}
+}
+
+// OfflineStoragePathE returns the path used for offline storage. It
+// returns an error if the request to the bridge fails.
+func (pg *WebPage) OfflineStoragePathE() (string, error) {
+	var v string
+	err := pg.getE("offline_storage_path", &v)
+	return v, err
+}
+
+// OfflineStoragePath returns the path used for offline storage. It
+// panics if the request to the bridge fails.
+func (pg *WebPage) OfflineStoragePath() string {
+	v, err := pg.OfflineStoragePathE()
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// OfflineStorageQuotaE returns the maximum size, in bytes, of offline
+// storage. It returns an error if the request to the bridge fails.
+func (pg *WebPage) OfflineStorageQuotaE() (int, error) {
+	var v int
+	err := pg.getE("offline_storage_quota", &v)
+	return v, err
+}
+
+// OfflineStorageQuota returns the maximum size, in bytes, of offline
+// storage. It panics if the request to the bridge fails.
+func (pg *WebPage) OfflineStorageQuota() int {
+	v, err := pg.OfflineStorageQuotaE()
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// OwnsPagesE returns true if windows opened by the page (e.g. via
+// target="_blank" links or window.open) are tracked as child pages. It
+// returns an error if the request to the bridge fails.
+func (pg *WebPage) OwnsPagesE() (bool, error) {
+	var v bool
+	err := pg.getE("owns_pages", &v)
+	return v, err
+}
+
+// OwnsPages returns true if windows opened by the page are tracked as
+// child pages. It panics if the request to the bridge fails.
+func (pg *WebPage) OwnsPages() bool {
+	v, err := pg.OwnsPagesE()
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// SetOwnsPagesE sets whether windows opened by the page are tracked as
+// child pages.
+func (pg *WebPage) SetOwnsPagesE(v bool) error {
+	return pg.setE("owns_pages", v)
+}
+
+// SetOwnsPages sets whether windows opened by the page are tracked as
+// child pages. It panics if the request to the bridge fails.
+func (pg *WebPage) SetOwnsPages(v bool) {
+	if err := pg.SetOwnsPagesE(v); err != nil {
+		panic(err)
+	}
+}
+
+// PageWindowNamesE returns the names of windows opened by the page. It
+// returns an error if the request to the bridge fails.
+func (pg *WebPage) PageWindowNamesE() ([]string, error) {
+	var v []string
+	err := pg.getE("page_window_names", &v)
+	return v, err
+}
+
+// PageWindowNames returns the names of windows opened by the page. It
+// panics if the request to the bridge fails.
+func (pg *WebPage) PageWindowNames() []string {
+	v, err := pg.PageWindowNamesE()
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// PagesE returns the child pages owned by the page. It returns an error
+// if the request to the bridge fails.
+func (pg *WebPage) PagesE() ([]*WebPage, error) {
+	var ids []int
+	if err := pg.getE("pages", &ids); err != nil {
+		return nil, err
+	}
+	pages := make([]*WebPage, len(ids))
+	for i, id := range ids {
+		pages[i] = newWebPage(pg.process, id)
+	}
+	return pages, nil
+}
+
+// Pages returns the child pages owned by the page. It panics if the
+// request to the bridge fails.
+func (pg *WebPage) Pages() []*WebPage {
+	v, err := pg.PagesE()
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// PagesContext returns the child pages owned by the page, aborting and
+// returning ctx.Err() if ctx is canceled before the bridge responds.
+func (pg *WebPage) PagesContext(ctx context.Context) ([]*WebPage, error) {
+	var ids []int
+	if err := pg.getContext(ctx, "pages", &ids); err != nil {
+		return nil, err
+	}
+	pages := make([]*WebPage, len(ids))
+	for i, id := range ids {
+		pages[i] = newWebPage(pg.process, id)
+	}
+	return pages, nil
+}
+
+// PaperSizeE returns the sizing options used when printing the page. It
+// returns an error if the request to the bridge fails.
+func (pg *WebPage) PaperSizeE() (PaperSize, error) {
+	var v PaperSize
+	err := pg.getE("paper_size", &v)
+	return v, err
+}
+
+// PaperSize returns the sizing options used when printing the page. It
+// panics if the request to the bridge fails.
+func (pg *WebPage) PaperSize() PaperSize {
+	v, err := pg.PaperSizeE()
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// SetPaperSizeE sets the sizing options used when printing the page.
+func (pg *WebPage) SetPaperSizeE(sz PaperSize) error {
+	return pg.setE("paper_size", sz)
+}
+
+// SetPaperSize sets the sizing options used when printing the page. It
+// panics if the request to the bridge fails.
+func (pg *WebPage) SetPaperSize(sz PaperSize) {
+	if err := pg.SetPaperSizeE(sz); err != nil {
+		panic(err)
+	}
+}
+
+// PlainTextE returns the plain-text rendering of the page. It returns an
+// error if the request to the bridge fails.
+func (pg *WebPage) PlainTextE() (string, error) {
+	var v string
+	err := pg.getE("plain_text", &v)
+	return v, err
+}
+
+// PlainText returns the plain-text rendering of the page. It panics if
+// the request to the bridge fails.
+func (pg *WebPage) PlainText() string {
+	v, err := pg.PlainTextE()
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// ScrollPositionE returns the scroll position of the page. It returns an
+// error if the request to the bridge fails.
+func (pg *WebPage) ScrollPositionE() (Position, error) {
+	var v Position
+	err := pg.getE("scroll_position", &v)
+	return v, err
+}
+
+// ScrollPosition returns the scroll position of the page. It panics if
+// the request to the bridge fails.
+func (pg *WebPage) ScrollPosition() Position {
+	v, err := pg.ScrollPositionE()
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// SetScrollPositionE sets the scroll position of the page.
+func (pg *WebPage) SetScrollPositionE(pos Position) error {
+	return pg.setE("scroll_position", pos)
+}
+
+// SetScrollPosition sets the scroll position of the page. It panics if
+// the request to the bridge fails.
+func (pg *WebPage) SetScrollPosition(pos Position) {
+	if err := pg.SetScrollPositionE(pos); err != nil {
+		panic(err)
+	}
+}
+
+// ContentE returns the HTML content of the page. It returns an error if
+// the request to the bridge fails.
+func (pg *WebPage) ContentE() (string, error) {
+	var v string
+	err := pg.getE("content", &v)
+	return v, err
+}
+
+// Content returns the HTML content of the page. It panics if the request
+// to the bridge fails.
+func (pg *WebPage) Content() string {
+	v, err := pg.ContentE()
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// SetContentE sets the HTML content of the page.
+func (pg *WebPage) SetContentE(content string) error {
+	return pg.setE("content", content)
+}
+
+// SetContent sets the HTML content of the page. It panics if the
+// request to the bridge fails.
+func (pg *WebPage) SetContent(content string) {
+	if err := pg.SetContentE(content); err != nil {
+		panic(err)
+	}
+}
+
+// URLE returns the current URL of the page. It returns an error if the
+// request to the bridge fails.
+func (pg *WebPage) URLE() (string, error) {
+	var v string
+	err := pg.getE("url", &v)
+	return v, err
+}
+
+// URL returns the current URL of the page. It panics if the request to
+// the bridge fails.
+func (pg *WebPage) URL() string {
+	v, err := pg.URLE()
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// OpenE navigates the page to url and waits for it to finish loading.
+// This is the primary way this package reports a failed navigation or an
+// unreachable bridge; Open is simply a thin alias kept for readability at
+// call sites that already handle an error return.
+func (pg *WebPage) OpenE(url string) error {
+	return pg.setE("open", url)
+}
+
+// Open navigates the page to url and waits for it to finish loading. It
+// returns an error if the navigation or the underlying bridge request
+// fails.
+func (pg *WebPage) Open(url string) error {
+	return pg.OpenE(url)
+}
+
+// OpenContext navigates the page to url and waits for it to finish
+// loading, aborting the in-flight bridge request and returning ctx.Err()
+// if ctx is canceled first. Use this to bound a hung navigation with
+// context.WithTimeout.
+func (pg *WebPage) OpenContext(ctx context.Context, url string) error {
+	return pg.setContext(ctx, "open", url)
+}
+
+// EvaluateJavaScriptE evaluates src, a JavaScript function expression,
+// in the context of the page and returns its result. It returns an
+// error if the request to the bridge fails.
+func (pg *WebPage) EvaluateJavaScriptE(src string) (interface{}, error) {
+	var v interface{}
+	err := pg.process.doJSON("POST", pg.path("evaluate_javascript"), src, &v)
+	return v, err
+}
+
+// EvaluateJavaScript evaluates src, a JavaScript function expression, in
+// the context of the page and returns its result. It panics if the
+// request to the bridge fails.
+func (pg *WebPage) EvaluateJavaScript(src string) interface{} {
+	v, err := pg.EvaluateJavaScriptE(src)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// EvaluateJavaScriptContext evaluates src, a JavaScript function
+// expression, in the context of the page and returns its result,
+// aborting and returning ctx.Err() if ctx is canceled before the bridge
+// responds.
+func (pg *WebPage) EvaluateJavaScriptContext(ctx context.Context, src string) (interface{}, error) {
+	var v interface{}
+	err := pg.process.doJSONContext(ctx, "POST", pg.path("evaluate_javascript"), src, &v)
+	return v, err
+}
+
+// UploadFile sets the files selected by the <input type="file"> element
+// matching selector, wiring into PhantomJS's page.uploadFile. This is
+// one of the few page interactions EvaluateJavaScript cannot reproduce,
+// since browsers refuse to let script set an <input type="file">'s
+// value. It returns an error if the element cannot be found or the
+// request to the bridge fails.
+func (pg *WebPage) UploadFile(selector string, paths ...string) error {
+	return pg.setE("upload_file", struct {
+		Selector string   `json:"selector"`
+		Paths    []string `json:"paths"`
+	}{selector, paths})
+}
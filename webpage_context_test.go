@@ -0,0 +1,52 @@
+package phantomjs_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/middlemost/phantomjs"
+)
+
+// Ensure OpenContext returns the context's error once it is canceled,
+// instead of blocking on a hung navigation.
+func TestWebPage_OpenContext(t *testing.T) {
+	// Server that never responds, to simulate a hung navigation.
+	block := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-block
+	}))
+	defer srv.Close()
+	defer close(block) // runs first, so srv.Close isn't left waiting on the handler
+
+	p := MustOpenNewProcess()
+	defer p.MustClose()
+
+	page := p.CreateWebPage()
+	defer page.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if err := page.OpenContext(ctx, srv.URL); err != context.DeadlineExceeded && err != context.Canceled {
+		t.Fatalf("expected context error, got %v", err)
+	}
+}
+
+// Ensure Process.OpenContext aborts and returns ctx.Err() rather than
+// blocking for the full bridge-ready timeout.
+func TestProcess_OpenContext(t *testing.T) {
+	// yes starts fine but never prints the bridge's ready line, so only
+	// ctx can end the wait.
+	p := phantomjs.NewProcess()
+	p.SetPath("yes")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := p.OpenContext(ctx); err != context.Canceled {
+		t.Fatalf("expected context canceled, got %v", err)
+	}
+}
@@ -0,0 +1,65 @@
+package phantomjs_test
+
+import (
+	"testing"
+
+	"github.com/middlemost/phantomjs"
+)
+
+// Ensure WebPage's *E methods surface a structured error, instead of
+// panicking, when the underlying process is unavailable.
+func TestWebPage_ErrorOnClosedProcess(t *testing.T) {
+	p := phantomjs.NewProcess()
+	page := p.CreateWebPage()
+
+	if _, err := page.CanGoForwardE(); err == nil {
+		t.Fatal("expected error")
+	}
+	if _, err := page.ClipRectE(); err == nil {
+		t.Fatal("expected error")
+	}
+	if _, err := page.CookiesE(); err == nil {
+		t.Fatal("expected error")
+	}
+	if _, err := page.FrameNamesE(); err == nil {
+		t.Fatal("expected error")
+	}
+	if err := page.OpenE("http://example.com"); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+// Ensure the panicking wrappers panic with the same error the *E variant
+// would have returned.
+func TestWebPage_PanicOnClosedProcess(t *testing.T) {
+	p := phantomjs.NewProcess()
+	page := p.CreateWebPage()
+
+	_, want := page.CanGoForwardE()
+	defer func() {
+		r := recover()
+		if r == nil {
+			t.Fatal("expected panic")
+		}
+		if err, ok := r.(error); !ok || err.Error() != want.Error() {
+			t.Fatalf("unexpected panic value: %v", r)
+		}
+	}()
+	page.CanGoForward()
+}
+
+// Ensure a killed process causes in-flight bridge requests to report an
+// error rather than hang or panic the test process.
+func TestWebPage_ErrorAfterProcessClose(t *testing.T) {
+	p := phantomjs.NewProcess()
+	if err := p.Open(); err == nil {
+		defer p.Close()
+	}
+
+	page := p.CreateWebPage()
+	p.Close()
+
+	if _, err := page.ContentE(); err == nil {
+		t.Fatal("expected error")
+	}
+}
@@ -0,0 +1,70 @@
+package phantomjs_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+)
+
+// Ensure web page can upload a file via an <input type="file"> element.
+func TestWebPage_UploadFile(t *testing.T) {
+	// Temp file to upload.
+	tmpfile, err := ioutil.TempFile("", "phantomjs-upload-*.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmpfile.Name())
+	if _, err := tmpfile.WriteString("FILE CONTENTS"); err != nil {
+		t.Fatal(err)
+	}
+	tmpfile.Close()
+
+	// Mock external HTTP server that records the submitted multipart body.
+	uploaded := make(chan []byte, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			w.Write([]byte(`<html><body><form id="form" action="/submit" method="post" enctype="multipart/form-data"><input id="upload" type="file" name="upload"/></form></body></html>`))
+		case "/submit":
+			r.ParseMultipartForm(1 << 20)
+			var body []byte
+			file, _, err := r.FormFile("upload")
+			if err == nil {
+				body, _ = ioutil.ReadAll(file)
+			}
+			uploaded <- body
+			w.Write([]byte(`<html><body>OK</body></html>`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	p := MustOpenNewProcess()
+	defer p.MustClose()
+
+	page := p.CreateWebPage()
+	defer page.Close()
+
+	if err := page.Open(srv.URL); err != nil {
+		t.Fatal(err)
+	}
+	if err := page.UploadFile("#upload", tmpfile.Name()); err != nil {
+		t.Fatal(err)
+	}
+	page.EvaluateJavaScript(`function() { document.getElementById("form").submit() }`)
+
+	// submit() returns before the form is posted, so wait for the server
+	// to receive it.
+	select {
+	case body := <-uploaded:
+		if string(body) != "FILE CONTENTS" {
+			t.Fatalf("unexpected upload body: %q", body)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the form submission")
+	}
+}